@@ -0,0 +1,180 @@
+package forecastpipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/forecast"
+	"github.com/aws/aws-sdk-go-v2/service/forecast/types"
+)
+
+// WindowMetrics is one forecast type's (e.g. "mean", "0.9") accuracy
+// metrics for a single backtest window.
+type WindowMetrics struct {
+	ForecastType string
+	WAPE         float64
+	RMSE         float64
+	MAPE         float64
+	MASE         float64
+}
+
+// QuantileLoss is the weighted quantile loss of a single quantile over a
+// backtest window.
+type QuantileLoss struct {
+	Quantile  float64
+	LossValue float64
+}
+
+// BacktestWindow is one algorithm's evaluation over a single backtest
+// window, or the average across all of a predictor's windows.
+type BacktestWindow struct {
+	ItemCount                   int32
+	AverageWeightedQuantileLoss float64
+	ErrorMetrics                []WindowMetrics
+	WeightedQuantileLosses      []QuantileLoss
+}
+
+// AccuracyReport flattens GetAccuracyMetrics' PredictorEvaluationResults
+// into the per-window metrics AWS Forecast computed while backtesting a
+// predictor, plus the average across all of them, since most callers only
+// care about the average and the per-window breakdown is secondary.
+type AccuracyReport struct {
+	PredictorArn string
+	Average      BacktestWindow
+	Windows      []BacktestWindow
+}
+
+// GetAccuracyMetrics fetches and flattens the backtest accuracy metrics AWS
+// Forecast computed while training predictorArn.
+func (f Forecast) GetAccuracyMetrics(ctx context.Context, predictorArn string) (*AccuracyReport, error) {
+	if f.DryRun {
+		log.Printf("[dry-run] would fetch accuracy metrics for predictor %s", predictorArn)
+		return &AccuracyReport{PredictorArn: predictorArn}, nil
+	}
+
+	out, err := f.svc.GetAccuracyMetrics(ctx, &forecast.GetAccuracyMetricsInput{
+		PredictorArn: aws.String(predictorArn),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AccuracyReport{PredictorArn: predictorArn}
+	for _, result := range out.PredictorEvaluationResults {
+		for _, w := range result.TestWindows {
+			window := backtestWindow(w)
+			if w.EvaluationType == types.EvaluationTypeSummary {
+				report.Average = window
+			} else {
+				report.Windows = append(report.Windows, window)
+			}
+		}
+	}
+	f.Notifier.OnComplete("predictor-accuracy", fmt.Sprintf("%s: avgWQL=%.4f", predictorArn, report.Average.AverageWeightedQuantileLoss))
+	return report, nil
+}
+
+func backtestWindow(w types.WindowSummary) BacktestWindow {
+	window := BacktestWindow{ItemCount: w.ItemCount}
+	if w.Metrics == nil {
+		return window
+	}
+	window.AverageWeightedQuantileLoss = aws.ToFloat64(w.Metrics.AverageWeightedQuantileLoss)
+	for _, m := range w.Metrics.ErrorMetrics {
+		window.ErrorMetrics = append(window.ErrorMetrics, WindowMetrics{
+			ForecastType: aws.ToString(m.ForecastType),
+			WAPE:         aws.ToFloat64(m.WAPE),
+			RMSE:         aws.ToFloat64(m.RMSE),
+			MAPE:         aws.ToFloat64(m.MAPE),
+			MASE:         aws.ToFloat64(m.MASE),
+		})
+	}
+	for _, q := range w.Metrics.WeightedQuantileLosses {
+		window.WeightedQuantileLosses = append(window.WeightedQuantileLosses, QuantileLoss{
+			Quantile:  aws.ToFloat64(q.Quantile),
+			LossValue: aws.ToFloat64(q.LossValue),
+		})
+	}
+	return window
+}
+
+// ReportFormat selects how WriteAccuracyReport renders an AccuracyReport.
+type ReportFormat string
+
+const (
+	ReportFormatMarkdown ReportFormat = "markdown"
+	ReportFormatJSON     ReportFormat = "json"
+)
+
+// WriteAccuracyReport renders report to w as Markdown or JSON. w can be a
+// local *os.File or any other io.Writer, including the writer side of an S3
+// upload, so callers decide where the report ends up.
+func WriteAccuracyReport(w io.Writer, report *AccuracyReport, format ReportFormat) error {
+	if format == ReportFormatJSON {
+		return json.NewEncoder(w).Encode(report)
+	}
+	_, err := io.WriteString(w, renderAccuracyReportMarkdown(report))
+	return err
+}
+
+func renderAccuracyReportMarkdown(report *AccuracyReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Accuracy report: %s\n\n", report.PredictorArn)
+	fmt.Fprintf(&b, "## Average across %d backtest window(s)\n\n", len(report.Windows))
+	writeBacktestWindowMarkdown(&b, report.Average)
+	for i, window := range report.Windows {
+		fmt.Fprintf(&b, "\n## Window %d\n\n", i+1)
+		writeBacktestWindowMarkdown(&b, window)
+	}
+	return b.String()
+}
+
+func writeBacktestWindowMarkdown(b *strings.Builder, window BacktestWindow) {
+	fmt.Fprintf(b, "Item count: %d  \nAverage weighted quantile loss: %.4f\n\n", window.ItemCount, window.AverageWeightedQuantileLoss)
+	if len(window.ErrorMetrics) > 0 {
+		fmt.Fprintln(b, "| Forecast type | WAPE | RMSE | MAPE | MASE |")
+		fmt.Fprintln(b, "|---|---|---|---|---|")
+		for _, m := range window.ErrorMetrics {
+			fmt.Fprintf(b, "| %s | %.4f | %.4f | %.4f | %.4f |\n", m.ForecastType, m.WAPE, m.RMSE, m.MAPE, m.MASE)
+		}
+	}
+	if len(window.WeightedQuantileLosses) > 0 {
+		fmt.Fprintln(b, "\n| Quantile | Loss |")
+		fmt.Fprintln(b, "|---|---|")
+		for _, q := range window.WeightedQuantileLosses {
+			fmt.Fprintf(b, "| %.2f | %.4f |\n", q.Quantile, q.LossValue)
+		}
+	}
+}
+
+// writeAccuracyReportFile fetches predictorArn's accuracy metrics and
+// writes them to a local file at path, in JSON if format is "json" and
+// Markdown otherwise. It is a no-op in DryRun, since GetAccuracyMetrics
+// returns no real metrics to report.
+func (f Forecast) writeAccuracyReportFile(ctx context.Context, predictorArn, path, format string) error {
+	if f.DryRun {
+		log.Printf("[dry-run] would write accuracy report for predictor %s to %s", predictorArn, path)
+		return nil
+	}
+	report, err := f.GetAccuracyMetrics(ctx, predictorArn)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reportFormat := ReportFormatMarkdown
+	if strings.EqualFold(format, "json") {
+		reportFormat = ReportFormatJSON
+	}
+	return WriteAccuracyReport(file, report, reportFormat)
+}
@@ -0,0 +1,74 @@
+package forecastpipeline
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/forecast/types"
+)
+
+func TestBacktestWindow(t *testing.T) {
+	w := types.WindowSummary{
+		ItemCount: 10,
+		Metrics: &types.Metrics{
+			AverageWeightedQuantileLoss: aws.Float64(0.25),
+			ErrorMetrics: []types.ErrorMetric{
+				{ForecastType: aws.String("mean"), WAPE: aws.Float64(0.1), RMSE: aws.Float64(1.2), MAPE: aws.Float64(0.2), MASE: aws.Float64(0.3)},
+			},
+			WeightedQuantileLosses: []types.WeightedQuantileLoss{
+				{Quantile: aws.Float64(0.5), LossValue: aws.Float64(0.15)},
+			},
+		},
+	}
+	got := backtestWindow(w)
+	if got.ItemCount != 10 {
+		t.Errorf("ItemCount = %d, want 10", got.ItemCount)
+	}
+	if got.AverageWeightedQuantileLoss != 0.25 {
+		t.Errorf("AverageWeightedQuantileLoss = %v, want 0.25", got.AverageWeightedQuantileLoss)
+	}
+	if len(got.ErrorMetrics) != 1 || got.ErrorMetrics[0].ForecastType != "mean" || got.ErrorMetrics[0].WAPE != 0.1 {
+		t.Errorf("ErrorMetrics = %+v, want one mean entry with WAPE 0.1", got.ErrorMetrics)
+	}
+	if len(got.WeightedQuantileLosses) != 1 || got.WeightedQuantileLosses[0].Quantile != 0.5 {
+		t.Errorf("WeightedQuantileLosses = %+v, want one entry with quantile 0.5", got.WeightedQuantileLosses)
+	}
+}
+
+func TestBacktestWindowNilMetrics(t *testing.T) {
+	got := backtestWindow(types.WindowSummary{ItemCount: 5})
+	if got.ItemCount != 5 || got.AverageWeightedQuantileLoss != 0 || got.ErrorMetrics != nil || got.WeightedQuantileLosses != nil {
+		t.Errorf("backtestWindow() with nil Metrics = %+v, want zero-value metrics with ItemCount 5", got)
+	}
+}
+
+func TestWriteAccuracyReportJSON(t *testing.T) {
+	report := &AccuracyReport{PredictorArn: "arn:aws:forecast:predictor/p1", Average: BacktestWindow{ItemCount: 3}}
+	var buf bytes.Buffer
+	if err := WriteAccuracyReport(&buf, report, ReportFormatJSON); err != nil {
+		t.Fatalf("WriteAccuracyReport() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"PredictorArn":"arn:aws:forecast:predictor/p1"`) {
+		t.Errorf("WriteAccuracyReport() JSON output %q missing PredictorArn", buf.String())
+	}
+}
+
+func TestWriteAccuracyReportMarkdown(t *testing.T) {
+	report := &AccuracyReport{
+		PredictorArn: "arn:aws:forecast:predictor/p1",
+		Average:      BacktestWindow{ItemCount: 3, AverageWeightedQuantileLoss: 0.1},
+		Windows:      []BacktestWindow{{ItemCount: 3, AverageWeightedQuantileLoss: 0.2}},
+	}
+	var buf bytes.Buffer
+	if err := WriteAccuracyReport(&buf, report, ReportFormatMarkdown); err != nil {
+		t.Fatalf("WriteAccuracyReport() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{"# Accuracy report: arn:aws:forecast:predictor/p1", "## Average across 1 backtest window(s)", "## Window 1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteAccuracyReport() markdown output %q does not contain %q", got, want)
+		}
+	}
+}
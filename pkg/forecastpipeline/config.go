@@ -0,0 +1,134 @@
+package forecastpipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a full Forecast pipeline run: the datasets to register,
+// the import jobs that load them from S3, the dataset group that ties them
+// together, the predictor to train and the forecast/export destinations.
+type Config struct {
+	DryRun         bool                 `yaml:"dryRun" json:"dryRun"`
+	MaxConcurrency int                  `yaml:"maxConcurrency" json:"maxConcurrency"`
+	PollTimeout    string               `yaml:"pollTimeout" json:"pollTimeout"` // e.g. "5m", "24h"; parsed with time.ParseDuration
+	Datasets       []DatasetConfig      `yaml:"datasets" json:"datasets"`
+	ImportJobs     []ImportJobConfig    `yaml:"importJobs" json:"importJobs"`
+	DatasetGroups  []DatasetGroupConfig `yaml:"datasetGroups" json:"datasetGroups"`
+	Predictor      PredictorConfig      `yaml:"predictor" json:"predictor"`
+	Forecast       ForecastConfig       `yaml:"forecast" json:"forecast"`
+	Export         ExportConfig         `yaml:"export" json:"export"`
+}
+
+type SchemaAttributeConfig struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"` // string, integer, float, timestamp, geolocation
+}
+
+type DatasetConfig struct {
+	Name       string                  `yaml:"name" json:"name"`
+	Type       string                  `yaml:"type" json:"type"` // target (default), related, item-metadata
+	Domain     string                  `yaml:"domain" json:"domain"`
+	Frequency  string                  `yaml:"frequency" json:"frequency"`
+	Attributes []SchemaAttributeConfig `yaml:"attributes" json:"attributes"`
+}
+
+type ImportJobConfig struct {
+	Name        string `yaml:"name" json:"name"`
+	DatasetName string `yaml:"datasetName" json:"datasetName"`
+	S3Path      string `yaml:"s3Path" json:"s3Path"`
+	RoleArn     string `yaml:"roleArn" json:"roleArn"`
+	TimeZone    string `yaml:"timeZone" json:"timeZone"`
+}
+
+type DatasetGroupConfig struct {
+	Name     string   `yaml:"name" json:"name"`
+	Domain   string   `yaml:"domain" json:"domain"`
+	Datasets []string `yaml:"datasets" json:"datasets"`
+}
+
+type PredictorConfig struct {
+	Name                  string   `yaml:"name" json:"name"`
+	Type                  string   `yaml:"type" json:"type"` // legacy (default) or auto
+	DatasetGroup          string   `yaml:"datasetGroup" json:"datasetGroup"`
+	ForecastHorizon       int32    `yaml:"forecastHorizon" json:"forecastHorizon"`
+	ForecastFrequency     string   `yaml:"forecastFrequency" json:"forecastFrequency"`
+	PerformAutoML         bool     `yaml:"performAutoML" json:"performAutoML"`
+	SupplementaryFeatures []string `yaml:"supplementaryFeatures" json:"supplementaryFeatures"` // "name:value", e.g. "holiday:US"
+
+	// Auto predictor (Type: auto) settings.
+	ForecastTypes      []string `yaml:"forecastTypes" json:"forecastTypes"` // e.g. "0.1", "0.5", "0.9", "mean"
+	ExplainPredictor   bool     `yaml:"explainPredictor" json:"explainPredictor"`
+	OptimizationMetric string   `yaml:"optimizationMetric" json:"optimizationMetric"` // e.g. WAPE, RMSE, MASE, MAPE, AverageWeightedQuantileLoss
+
+	// AccuracyReportPath, if set, renders the predictor's backtest accuracy
+	// to a local file at this path once training completes.
+	AccuracyReportPath string `yaml:"accuracyReportPath" json:"accuracyReportPath"`
+	// AccuracyReportFormat is "markdown" (default) or "json".
+	AccuracyReportFormat string `yaml:"accuracyReportFormat" json:"accuracyReportFormat"`
+	// BacktestExport, if Name is set, persists the predictor's per-item
+	// backtest predictions to S3 for offline analysis alongside the
+	// accuracy report.
+	BacktestExport BacktestExportConfig `yaml:"backtestExport" json:"backtestExport"`
+}
+
+type BacktestExportConfig struct {
+	Name    string `yaml:"name" json:"name"`
+	S3Path  string `yaml:"s3Path" json:"s3Path"`
+	RoleArn string `yaml:"roleArn" json:"roleArn"`
+}
+
+type ForecastConfig struct {
+	Name      string `yaml:"name" json:"name"`
+	Predictor string `yaml:"predictor" json:"predictor"`
+}
+
+type ExportConfig struct {
+	Name     string `yaml:"name" json:"name"`
+	Forecast string `yaml:"forecast" json:"forecast"`
+	S3Path   string `yaml:"s3Path" json:"s3Path"`
+	RoleArn  string `yaml:"roleArn" json:"roleArn"`
+}
+
+// PollTimeoutDuration parses PollTimeout, returning 0 (no override) when it
+// is unset.
+func (c Config) PollTimeoutDuration() (time.Duration, error) {
+	if c.PollTimeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(c.PollTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("parsing pollTimeout %q: %w", c.PollTimeout, err)
+	}
+	return d, nil
+}
+
+// LoadConfig reads a pipeline Config from a YAML or JSON file, chosen by the
+// file's extension (.yaml/.yml or .json).
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config extension %q", ext)
+	}
+	return &cfg, nil
+}
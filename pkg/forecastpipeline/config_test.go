@@ -0,0 +1,60 @@
+package forecastpipeline
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPollTimeoutDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"unset", "", 0, false},
+		{"minutes", "5m", 5 * time.Minute, false},
+		{"hours", "24h", 24 * time.Hour, false},
+		{"invalid", "5 minutes", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{PollTimeout: tt.raw}
+			got, err := cfg.PollTimeoutDuration()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("PollTimeoutDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("PollTimeoutDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigYAML(t *testing.T) {
+	cfg, err := LoadConfig(filepath.Join("..", "..", "forecast.yaml"))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.MaxConcurrency != 4 {
+		t.Errorf("MaxConcurrency = %d, want 4", cfg.MaxConcurrency)
+	}
+	if len(cfg.Datasets) != 1 || cfg.Datasets[0].Name != "electricityusagedata" {
+		t.Errorf("Datasets = %+v, want one dataset named electricityusagedata", cfg.Datasets)
+	}
+	if cfg.Predictor.Name != "electricityusagedata_predictor" {
+		t.Errorf("Predictor.Name = %q, want electricityusagedata_predictor", cfg.Predictor.Name)
+	}
+}
+
+func TestLoadConfigUnsupportedExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "forecast.txt")
+	if err := os.WriteFile(path, []byte("dryRun: true"), 0o644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadConfig(path); err == nil {
+		t.Error("LoadConfig() with unsupported extension: want error, got nil")
+	}
+}
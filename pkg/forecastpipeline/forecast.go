@@ -0,0 +1,613 @@
+// Package forecastpipeline drives the Amazon Forecast dataset -> predictor ->
+// forecast -> export lifecycle so it can be reused outside of the example
+// CLI in this repository.
+package forecastpipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/forecast"
+	"github.com/aws/aws-sdk-go-v2/service/forecast/types"
+	"github.com/aws/aws-sdk-go-v2/service/forecastquery"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+type Forecast struct {
+	region string
+	svc    *forecast.Client
+	query  *forecastquery.Client
+	caller *sts.GetCallerIdentityOutput
+
+	// DryRun logs every Create*/Delete* API call it would issue, including
+	// resolved ARNs and inputs, without invoking the SDK. It lets callers
+	// validate a config against a real AWS account without incurring
+	// predictor training costs.
+	DryRun bool
+	// MaxConcurrency bounds how many independent CreateDatasetImportJob
+	// calls Run executes at once. Import jobs can take an hour each and
+	// are embarrassingly parallel, so this defaults to 1 (sequential) and
+	// should be raised when a config declares several of them.
+	MaxConcurrency int
+	// PollTimeout bounds how long waitForActive/waitForDeleted poll a
+	// resource before giving up. Defaults to defaultPollTimeout.
+	PollTimeout time.Duration
+	// Notifier is told about every resource waitForActive/waitForDeleted
+	// polls. Defaults to StdoutNotifier.
+	Notifier Notifier
+}
+
+type Options struct {
+	DryRun         bool
+	MaxConcurrency int
+	PollTimeout    time.Duration
+	Notifier       Notifier
+}
+
+const (
+	minPollInterval    = 15 * time.Second
+	maxPollInterval    = 5 * time.Minute
+	defaultPollTimeout = 24 * time.Hour
+)
+
+func New(ctx context.Context, awsCfg aws.Config, opts Options) (*Forecast, error) {
+	caller, err := sts.NewFromConfig(awsCfg).GetCallerIdentity(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	maxConcurrency := opts.MaxConcurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 1
+	}
+	pollTimeout := opts.PollTimeout
+	if pollTimeout <= 0 {
+		pollTimeout = defaultPollTimeout
+	}
+	notifier := opts.Notifier
+	if notifier == nil {
+		notifier = StdoutNotifier{}
+	}
+	return &Forecast{
+		region:         awsCfg.Region,
+		svc:            forecast.NewFromConfig(awsCfg),
+		query:          forecastquery.NewFromConfig(awsCfg),
+		caller:         caller,
+		DryRun:         opts.DryRun,
+		MaxConcurrency: maxConcurrency,
+		PollTimeout:    pollTimeout,
+		Notifier:       notifier,
+	}, nil
+}
+
+func (f Forecast) dryRunArn(resource, name string) *string {
+	return aws.String(fmt.Sprintf("arn:aws:forecast:%s:%s:%s/%s", f.region, *f.caller.Account, resource, name))
+}
+
+// logDryRun reports the API call that would have been issued and returns a
+// synthesized ARN for the resource, so that dry-run pipelines can still
+// chain a Create call's output into the next step.
+func (f Forecast) logDryRun(resource, name, input string) *string {
+	log.Printf("[dry-run] would create %s %q: %s", resource, name, input)
+	return f.dryRunArn(resource, name)
+}
+
+func (f Forecast) logDeleteDryRun(resource, arn string) {
+	log.Printf("[dry-run] would delete %s %s", resource, arn)
+}
+
+func (f Forecast) skipIfAlreadyExists(resource string, name string, h func() (*string, error)) (*string, error) {
+	arn, err := h()
+	if err != nil {
+		var exists *types.ResourceAlreadyExistsException
+		if errors.As(err, &exists) {
+			log.Printf("skip to create %s already exists", resource)
+			return aws.String(fmt.Sprintf("arn:aws:forecast:%s:%s:%s/%s", f.region, *f.caller.Account, resource, name)), nil
+		}
+		return nil, err
+	}
+	return arn, nil
+}
+
+// nextPollInterval backs off exponentially from minPollInterval up to
+// maxPollInterval, unless the API reported how much longer the job is
+// expected to take, in which case it sleeps for about half of that instead.
+func nextPollInterval(prev time.Duration, remainingMin *int64) time.Duration {
+	if remainingMin != nil {
+		if remaining := time.Duration(*remainingMin) * time.Minute / 2; remaining < maxPollInterval {
+			if remaining < minPollInterval {
+				return minPollInterval
+			}
+			return remaining
+		}
+		return maxPollInterval
+	}
+	next := prev * 2
+	if next > maxPollInterval {
+		return maxPollInterval
+	}
+	return next
+}
+
+func (f Forecast) waitForActive(ctx context.Context, name, arn string, h func() (*string, *int64, error)) error {
+	f.Notifier.OnStart(name, arn)
+	ctx, cancel := context.WithTimeout(ctx, f.PollTimeout)
+	defer cancel()
+
+	interval := minPollInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			status, remainingMin, err := h()
+			if err != nil {
+				f.Notifier.OnError(name, err)
+				return err
+			}
+			f.Notifier.OnStatusChange(name, arn, *status, remainingMin)
+			if *status == "ACTIVE" {
+				f.Notifier.OnComplete(name, arn)
+				return nil
+			} else if !strings.HasPrefix(*status, "CREATE") {
+				err := fmt.Errorf("%s is not creating but %s", name, *status)
+				f.Notifier.OnError(name, err)
+				return err
+			} else if *status == "CREATE_FAILED" {
+				err := errors.New("creating is failed")
+				f.Notifier.OnError(name, err)
+				return err
+			}
+			interval = nextPollInterval(interval, remainingMin)
+			timer.Reset(interval)
+		case <-ctx.Done():
+			f.Notifier.OnError(name, ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+// CreateDataset registers a dataset of any DatasetType (target time series,
+// related time series or item metadata) with the given domain, frequency
+// and schema. Related time series and item metadata datasets are what let a
+// dataset group carry covariates (price, promotions, weather) and per-item
+// attributes alongside the target series.
+func (f Forecast) CreateDataset(ctx context.Context, name string, datasetType types.DatasetType, domain types.Domain, frequency string, attributes []types.SchemaAttribute) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun("dataset", name, fmt.Sprintf("DatasetType=%s, Domain=%s, DataFrequency=%s, Schema=%s", datasetType, domain, frequency, schemaAttributesString(attributes))), nil
+	}
+	return f.skipIfAlreadyExists("dataset", name, func() (*string, error) {
+		input := &forecast.CreateDatasetInput{
+			DatasetName: aws.String(name),
+			DatasetType: datasetType,
+			Domain:      domain,
+			Schema:      &types.Schema{Attributes: attributes},
+		}
+		if frequency != "" {
+			input.DataFrequency = aws.String(frequency)
+		}
+		dataset, err := f.svc.CreateDataset(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return dataset.DatasetArn, nil
+	})
+}
+
+// schemaAttributesString renders attrs as "name:type, ..." for dry-run
+// logging, since SchemaAttribute's fields are *string and would otherwise
+// print as pointer addresses with %v.
+func schemaAttributesString(attrs []types.SchemaAttribute) string {
+	parts := make([]string, 0, len(attrs))
+	for _, a := range attrs {
+		parts = append(parts, fmt.Sprintf("%s:%s", aws.ToString(a.AttributeName), a.AttributeType))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (f Forecast) CreateDatasetImportJob(ctx context.Context, name, datasetName, datasetArn, timeZone string, src *types.S3Config) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun(fmt.Sprintf("dataset-import-job/%s", datasetName), name,
+			fmt.Sprintf("DatasetArn=%s, S3Path=%s, RoleArn=%s, TimeZone=%s", datasetArn, aws.ToString(src.Path), aws.ToString(src.RoleArn), timeZone)), nil
+	}
+	arn, err := f.skipIfAlreadyExists(fmt.Sprintf("dataset-import-job/%s", datasetName), name, func() (*string, error) {
+		input := &forecast.CreateDatasetImportJobInput{
+			DatasetImportJobName: aws.String(name),
+			DatasetArn:           &datasetArn,
+			DataSource: &types.DataSource{
+				S3Config: src,
+			},
+		}
+		if timeZone != "" {
+			input.TimeZone = aws.String(timeZone)
+		}
+		job, err := f.svc.CreateDatasetImportJob(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return job.DatasetImportJobArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "dataset-import-job", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribeDatasetImportJob(ctx, &forecast.DescribeDatasetImportJobInput{
+			DatasetImportJobArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return desc.Status, desc.EstimatedTimeRemainingInMinutes, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+func (f Forecast) CreateDatasetGroup(ctx context.Context, name string, domain types.Domain, datasetArns []string) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun("dataset-group", name, fmt.Sprintf("Domain=%s, DatasetArns=%v", domain, datasetArns)), nil
+	}
+	return f.skipIfAlreadyExists("dataset-group", name, func() (*string, error) {
+		datasetGroup, err := f.svc.CreateDatasetGroup(ctx, &forecast.CreateDatasetGroupInput{
+			DatasetGroupName: aws.String(name),
+			DatasetArns:      datasetArns,
+			Domain:           domain,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return datasetGroup.DatasetGroupArn, nil
+	})
+}
+
+// AutoPredictorOptions configures a CreateAutoPredictor call.
+type AutoPredictorOptions struct {
+	ForecastHorizon   int32
+	ForecastFrequency string
+	// ForecastTypes selects which quantiles (and optionally "mean") the
+	// predictor forecasts, e.g. []string{"0.1", "0.5", "0.9", "mean"}.
+	ForecastTypes      []string
+	ExplainPredictor   bool
+	OptimizationMetric string // e.g. WAPE, RMSE, MASE, MAPE, AverageWeightedQuantileLoss
+}
+
+// CreateAutoPredictor trains a predictor with AWS's AutoPredictor
+// (CreateAutoPredictor API) instead of the legacy CreatePredictor used by
+// createPredictor. It supports explainability and per-quantile forecast
+// types that the legacy API does not.
+func (f Forecast) CreateAutoPredictor(ctx context.Context, name, datasetGroupArn string, opts AutoPredictorOptions) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun("predictor", name, fmt.Sprintf("DatasetGroupArn=%s, ForecastHorizon=%d, ForecastTypes=%v, OptimizationMetric=%s",
+			datasetGroupArn, opts.ForecastHorizon, opts.ForecastTypes, opts.OptimizationMetric)), nil
+	}
+	arn, err := f.skipIfAlreadyExists("predictor", name, func() (*string, error) {
+		predictor, err := f.svc.CreateAutoPredictor(ctx, &forecast.CreateAutoPredictorInput{
+			PredictorName:      aws.String(name),
+			ForecastHorizon:    aws.Int32(opts.ForecastHorizon),
+			ForecastFrequency:  aws.String(opts.ForecastFrequency),
+			ForecastTypes:      opts.ForecastTypes,
+			ExplainPredictor:   aws.Bool(opts.ExplainPredictor),
+			OptimizationMetric: types.OptimizationMetric(opts.OptimizationMetric),
+			DataConfig: &types.DataConfig{
+				DatasetGroupArn: aws.String(datasetGroupArn),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return predictor.PredictorArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "predictor", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribeAutoPredictor(ctx, &forecast.DescribeAutoPredictorInput{
+			PredictorArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		status := string(desc.Status)
+		return &status, desc.EstimatedTimeRemainingInMinutes, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+func (f Forecast) CreateForecast(ctx context.Context, name, predictorArn string) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun("forecast", name, fmt.Sprintf("PredictorArn=%s", predictorArn)), nil
+	}
+	arn, err := f.skipIfAlreadyExists("forecast", name, func() (*string, error) {
+		fc, err := f.svc.CreateForecast(ctx, &forecast.CreateForecastInput{
+			ForecastName: aws.String(name),
+			PredictorArn: aws.String(predictorArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fc.ForecastArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "forecast", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribeForecast(ctx, &forecast.DescribeForecastInput{
+			ForecastArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return desc.Status, desc.EstimatedTimeRemainingInMinutes, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+func (f Forecast) CreateForecastExportJob(ctx context.Context, name, forecastName, forecastArn string, dest *types.S3Config) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun(fmt.Sprintf("forecast-export-job/%s", forecastName), name,
+			fmt.Sprintf("ForecastArn=%s, S3Path=%s, RoleArn=%s", forecastArn, aws.ToString(dest.Path), aws.ToString(dest.RoleArn))), nil
+	}
+	arn, err := f.skipIfAlreadyExists(fmt.Sprintf("forecast-export-job/%s", forecastName), name, func() (*string, error) {
+		job, err := f.svc.CreateForecastExportJob(ctx, &forecast.CreateForecastExportJobInput{
+			ForecastExportJobName: aws.String(name),
+			ForecastArn:           aws.String(forecastArn),
+			Destination: &types.DataDestination{
+				S3Config: dest,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return job.ForecastExportJobArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "forecast-export-job", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribeForecastExportJob(ctx, &forecast.DescribeForecastExportJobInput{
+			ForecastExportJobArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return desc.Status, nil, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+// CreatePredictorBacktestExportJob persists predictorArn's per-item backtest
+// predictions to dest, mirroring CreateForecastExportJob so they can be
+// analyzed offline alongside GetAccuracyMetrics' aggregate metrics.
+func (f Forecast) CreatePredictorBacktestExportJob(ctx context.Context, name, predictorName, predictorArn string, dest *types.S3Config) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun(fmt.Sprintf("predictor-backtest-export-job/%s", predictorName), name,
+			fmt.Sprintf("PredictorArn=%s, S3Path=%s, RoleArn=%s", predictorArn, aws.ToString(dest.Path), aws.ToString(dest.RoleArn))), nil
+	}
+	arn, err := f.skipIfAlreadyExists(fmt.Sprintf("predictor-backtest-export-job/%s", predictorName), name, func() (*string, error) {
+		job, err := f.svc.CreatePredictorBacktestExportJob(ctx, &forecast.CreatePredictorBacktestExportJobInput{
+			PredictorBacktestExportJobName: aws.String(name),
+			PredictorArn:                   aws.String(predictorArn),
+			Destination: &types.DataDestination{
+				S3Config: dest,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return job.PredictorBacktestExportJobArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "predictor-backtest-export-job", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribePredictorBacktestExportJob(ctx, &forecast.DescribePredictorBacktestExportJobInput{
+			PredictorBacktestExportJobArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return desc.Status, nil, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+func (f Forecast) waitForDeleted(ctx context.Context, name, arn string, h func() (*string, error)) error {
+	f.Notifier.OnStart(name, arn)
+	ctx, cancel := context.WithTimeout(ctx, f.PollTimeout)
+	defer cancel()
+
+	interval := minPollInterval
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			status, err := h()
+			if err != nil {
+				var notFound *types.ResourceNotFoundException
+				if errors.As(err, &notFound) {
+					f.Notifier.OnComplete(name, arn)
+					return nil
+				}
+				f.Notifier.OnError(name, err)
+				return err
+			}
+			f.Notifier.OnStatusChange(name, arn, *status, nil)
+			if !strings.HasPrefix(*status, "DELETE") {
+				err := fmt.Errorf("%s is not deleting but %s", name, *status)
+				f.Notifier.OnError(name, err)
+				return err
+			} else if *status == "DELETE_FAILED" {
+				err := errors.New("deleting is failed")
+				f.Notifier.OnError(name, err)
+				return err
+			}
+			interval = nextPollInterval(interval, nil)
+			timer.Reset(interval)
+		case <-ctx.Done():
+			f.Notifier.OnError(name, ctx.Err())
+			return ctx.Err()
+		}
+	}
+}
+
+func (f Forecast) DeleteForecastExportJob(ctx context.Context, forecastExportJobArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("forecast-export-job", forecastExportJobArn)
+		return nil
+	}
+	_, err := f.svc.DeleteForecastExportJob(ctx, &forecast.DeleteForecastExportJobInput{
+		ForecastExportJobArn: aws.String(forecastExportJobArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.waitForDeleted(ctx, "forecast-export-job", forecastExportJobArn, func() (*string, error) {
+		desc, err := f.svc.DescribeForecastExportJob(ctx, &forecast.DescribeForecastExportJobInput{
+			ForecastExportJobArn: aws.String(forecastExportJobArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return desc.Status, nil
+	})
+}
+
+func (f Forecast) DeleteForecast(ctx context.Context, forecastArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("forecast", forecastArn)
+		return nil
+	}
+	_, err := f.svc.DeleteForecast(ctx, &forecast.DeleteForecastInput{
+		ForecastArn: aws.String(forecastArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.waitForDeleted(ctx, "forecast", forecastArn, func() (*string, error) {
+		desc, err := f.svc.DescribeForecast(ctx, &forecast.DescribeForecastInput{
+			ForecastArn: aws.String(forecastArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return desc.Status, nil
+	})
+}
+
+func (f Forecast) DeletePredictor(ctx context.Context, predictorArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("predictor", predictorArn)
+		return nil
+	}
+	_, err := f.svc.DeletePredictor(ctx, &forecast.DeletePredictorInput{
+		PredictorArn: aws.String(predictorArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.waitForDeleted(ctx, "predictor", predictorArn, func() (*string, error) {
+		desc, err := f.svc.DescribePredictor(ctx, &forecast.DescribePredictorInput{
+			PredictorArn: aws.String(predictorArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return desc.Status, nil
+	})
+}
+
+func (f Forecast) DeleteDatasetGroup(ctx context.Context, datasetGroupArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("dataset-group", datasetGroupArn)
+		return nil
+	}
+	_, err := f.svc.DeleteDatasetGroup(ctx, &forecast.DeleteDatasetGroupInput{
+		DatasetGroupArn: aws.String(datasetGroupArn),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (f Forecast) DeleteDatasetImportJob(ctx context.Context, datasetImportJobArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("dataset-import-job", datasetImportJobArn)
+		return nil
+	}
+	_, err := f.svc.DeleteDatasetImportJob(ctx, &forecast.DeleteDatasetImportJobInput{
+		DatasetImportJobArn: aws.String(datasetImportJobArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.waitForDeleted(ctx, "dataset-import-job", datasetImportJobArn, func() (*string, error) {
+		desc, err := f.svc.DescribeDatasetImportJob(ctx, &forecast.DescribeDatasetImportJobInput{
+			DatasetImportJobArn: aws.String(datasetImportJobArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return desc.Status, nil
+	})
+}
+
+func (f Forecast) DeletePredictorBacktestExportJob(ctx context.Context, predictorBacktestExportJobArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("predictor-backtest-export-job", predictorBacktestExportJobArn)
+		return nil
+	}
+	_, err := f.svc.DeletePredictorBacktestExportJob(ctx, &forecast.DeletePredictorBacktestExportJobInput{
+		PredictorBacktestExportJobArn: aws.String(predictorBacktestExportJobArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	return f.waitForDeleted(ctx, "predictor-backtest-export-job", predictorBacktestExportJobArn, func() (*string, error) {
+		desc, err := f.svc.DescribePredictorBacktestExportJob(ctx, &forecast.DescribePredictorBacktestExportJobInput{
+			PredictorBacktestExportJobArn: aws.String(predictorBacktestExportJobArn),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return desc.Status, nil
+	})
+}
+
+func (f Forecast) DeleteDataset(ctx context.Context, datasetArn string) error {
+	if f.DryRun {
+		f.logDeleteDryRun("dataset", datasetArn)
+		return nil
+	}
+	_, err := f.svc.DeleteDataset(ctx, &forecast.DeleteDatasetInput{
+		DatasetArn: aws.String(datasetArn),
+	})
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package forecastpipeline
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextPollInterval(t *testing.T) {
+	tests := []struct {
+		name         string
+		prev         time.Duration
+		remainingMin *int64
+		want         time.Duration
+	}{
+		{"doubles without remainingMin", 15 * time.Second, nil, 30 * time.Second},
+		{"clamps to max without remainingMin", 4 * time.Minute, nil, maxPollInterval},
+		{"half of remainingMin", 15 * time.Second, int64Ptr(6), 3 * time.Minute},
+		{"clamps to min when half of remainingMin is smaller", 15 * time.Second, int64Ptr(0), minPollInterval},
+		{"clamps to max when remainingMin is large", 15 * time.Second, int64Ptr(60), maxPollInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPollInterval(tt.prev, tt.remainingMin); got != tt.want {
+				t.Errorf("nextPollInterval(%v, %v) = %v, want %v", tt.prev, tt.remainingMin, got, tt.want)
+			}
+		})
+	}
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
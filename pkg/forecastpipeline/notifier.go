@@ -0,0 +1,201 @@
+package forecastpipeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+// Notifier observes the lifecycle of a resource waitForActive/waitForDeleted
+// is polling, so operators can be told when a multi-hour predictor training
+// or forecast generation finishes without tailing logs. Implementations
+// should not block the poll loop for long or return an error; report
+// delivery failures through their own logging instead.
+type Notifier interface {
+	OnStart(resource, arn string)
+	OnStatusChange(resource, arn, status string, remainingMin *int64)
+	OnComplete(resource, arn string)
+	OnError(resource string, err error)
+}
+
+// StdoutNotifier reproduces the plain log.Printf behavior this package had
+// before Notifier existed, and is the default when none is configured.
+type StdoutNotifier struct{}
+
+func (StdoutNotifier) OnStart(resource, arn string) {
+	log.Printf("%s %s: waiting", resource, arn)
+}
+
+func (StdoutNotifier) OnStatusChange(resource, arn, status string, remainingMin *int64) {
+	if remainingMin != nil {
+		log.Printf("%s's status is %s. remaining %d mins", resource, status, *remainingMin)
+	} else {
+		log.Printf("%s's status is %s", resource, status)
+	}
+}
+
+func (StdoutNotifier) OnComplete(resource, arn string) {
+	log.Printf("%s %s: done", resource, arn)
+}
+
+func (StdoutNotifier) OnError(resource string, err error) {
+	log.Printf("%s: %v", resource, err)
+}
+
+// MultiNotifier fans a single event out to every Notifier in it.
+type MultiNotifier []Notifier
+
+func (m MultiNotifier) OnStart(resource, arn string) {
+	for _, n := range m {
+		n.OnStart(resource, arn)
+	}
+}
+
+func (m MultiNotifier) OnStatusChange(resource, arn, status string, remainingMin *int64) {
+	for _, n := range m {
+		n.OnStatusChange(resource, arn, status, remainingMin)
+	}
+}
+
+func (m MultiNotifier) OnComplete(resource, arn string) {
+	for _, n := range m {
+		n.OnComplete(resource, arn)
+	}
+}
+
+func (m MultiNotifier) OnError(resource string, err error) {
+	for _, n := range m {
+		n.OnError(resource, err)
+	}
+}
+
+// SlackNotifier posts to a Slack incoming webhook. Only completion and error
+// events are posted; status changes are too frequent to be useful in chat.
+type SlackNotifier struct {
+	WebhookURL string
+	HTTPClient *http.Client
+}
+
+func (s SlackNotifier) client() *http.Client {
+	if s.HTTPClient != nil {
+		return s.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (s SlackNotifier) post(text string) {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		log.Printf("slack notifier: %v", err)
+		return
+	}
+	resp, err := s.client().Post(s.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("slack notifier: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("slack notifier: webhook returned %s", resp.Status)
+	}
+}
+
+func (SlackNotifier) OnStart(resource, arn string) {}
+
+func (SlackNotifier) OnStatusChange(resource, arn, status string, remainingMin *int64) {}
+
+func (s SlackNotifier) OnComplete(resource, arn string) {
+	s.post(fmt.Sprintf(":white_check_mark: %s is ready: %s", resource, arn))
+}
+
+func (s SlackNotifier) OnError(resource string, err error) {
+	s.post(fmt.Sprintf(":x: %s failed: %v", resource, err))
+}
+
+// HTTPNotifier POSTs a JSON payload describing each event to URL.
+type HTTPNotifier struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+type httpNotifierPayload struct {
+	Event        string `json:"event"`
+	Resource     string `json:"resource"`
+	Arn          string `json:"arn,omitempty"`
+	Status       string `json:"status,omitempty"`
+	RemainingMin *int64 `json:"remainingMin,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+func (h HTTPNotifier) client() *http.Client {
+	if h.HTTPClient != nil {
+		return h.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (h HTTPNotifier) post(payload httpNotifierPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("http notifier: %v", err)
+		return
+	}
+	resp, err := h.client().Post(h.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Printf("http notifier: %v", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		log.Printf("http notifier: %s returned %s", h.URL, resp.Status)
+	}
+}
+
+func (h HTTPNotifier) OnStart(resource, arn string) {
+	h.post(httpNotifierPayload{Event: "start", Resource: resource, Arn: arn})
+}
+
+func (h HTTPNotifier) OnStatusChange(resource, arn, status string, remainingMin *int64) {
+	h.post(httpNotifierPayload{Event: "status_change", Resource: resource, Arn: arn, Status: status, RemainingMin: remainingMin})
+}
+
+func (h HTTPNotifier) OnComplete(resource, arn string) {
+	h.post(httpNotifierPayload{Event: "complete", Resource: resource, Arn: arn})
+}
+
+func (h HTTPNotifier) OnError(resource string, err error) {
+	h.post(httpNotifierPayload{Event: "error", Resource: resource, Error: err.Error()})
+}
+
+// SNSNotifier publishes completion and error events to an SNS topic.
+type SNSNotifier struct {
+	TopicArn string
+	Client   *sns.Client
+}
+
+func (s SNSNotifier) publish(message string) {
+	if _, err := s.Client.Publish(context.Background(), &sns.PublishInput{
+		TopicArn: aws.String(s.TopicArn),
+		Message:  aws.String(message),
+	}); err != nil {
+		log.Printf("sns notifier: %v", err)
+	}
+}
+
+func (SNSNotifier) OnStart(resource, arn string) {}
+
+func (SNSNotifier) OnStatusChange(resource, arn, status string, remainingMin *int64) {}
+
+func (s SNSNotifier) OnComplete(resource, arn string) {
+	s.publish(fmt.Sprintf("%s is ready: %s", resource, arn))
+}
+
+func (s SNSNotifier) OnError(resource string, err error) {
+	s.publish(fmt.Sprintf("%s failed: %v", resource, err))
+}
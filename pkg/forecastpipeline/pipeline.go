@@ -0,0 +1,292 @@
+package forecastpipeline
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/forecast"
+	"github.com/aws/aws-sdk-go-v2/service/forecast/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// Run drives the full dataset -> import job -> dataset group -> predictor ->
+// forecast -> export lifecycle described by cfg, and cleans up every
+// resource it created once the export job is done.
+func (f Forecast) Run(ctx context.Context, cfg Config) error {
+	datasetArns := map[string]string{}
+	for _, d := range cfg.Datasets {
+		log.Printf("create dataset %s", d.Name)
+		arn, err := f.createDataset(ctx, d)
+		if err != nil {
+			return err
+		}
+		datasetArns[d.Name] = *arn
+	}
+
+	importJobArns, err := f.createDatasetImportJobs(ctx, cfg.ImportJobs, datasetArns)
+	if err != nil {
+		return err
+	}
+
+	datasetGroupArns := map[string]string{}
+	for _, g := range cfg.DatasetGroups {
+		arns := make([]string, 0, len(g.Datasets))
+		for _, name := range g.Datasets {
+			arn, ok := datasetArns[name]
+			if !ok {
+				return fmt.Errorf("dataset group %s references unknown dataset %s", g.Name, name)
+			}
+			arns = append(arns, arn)
+		}
+		log.Printf("create datasetGroup %s", g.Name)
+		arn, err := f.CreateDatasetGroup(ctx, g.Name, domain(g.Domain), arns)
+		if err != nil {
+			return err
+		}
+		datasetGroupArns[g.Name] = *arn
+	}
+
+	datasetGroupArn, ok := datasetGroupArns[cfg.Predictor.DatasetGroup]
+	if !ok {
+		return fmt.Errorf("predictor %s references unknown dataset group %s", cfg.Predictor.Name, cfg.Predictor.DatasetGroup)
+	}
+
+	log.Println("create predictor")
+	var predictorArn *string
+	if strings.EqualFold(cfg.Predictor.Type, "auto") {
+		predictorArn, err = f.CreateAutoPredictor(ctx, cfg.Predictor.Name, datasetGroupArn, AutoPredictorOptions{
+			ForecastHorizon:    cfg.Predictor.ForecastHorizon,
+			ForecastFrequency:  cfg.Predictor.ForecastFrequency,
+			ForecastTypes:      cfg.Predictor.ForecastTypes,
+			ExplainPredictor:   cfg.Predictor.ExplainPredictor,
+			OptimizationMetric: cfg.Predictor.OptimizationMetric,
+		})
+	} else {
+		predictorArn, err = f.createPredictor(ctx, cfg.Predictor, datasetGroupArn)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cfg.Predictor.AccuracyReportPath != "" {
+		log.Println("write accuracy report")
+		if err := f.writeAccuracyReportFile(ctx, *predictorArn, cfg.Predictor.AccuracyReportPath, cfg.Predictor.AccuracyReportFormat); err != nil {
+			return err
+		}
+	}
+
+	var backtestExportJobArn *string
+	if cfg.Predictor.BacktestExport.Name != "" {
+		log.Println("create predictorBacktestExportJob")
+		backtestExportJobArn, err = f.CreatePredictorBacktestExportJob(ctx, cfg.Predictor.BacktestExport.Name, cfg.Predictor.Name, *predictorArn, &types.S3Config{
+			Path:    aws.String(cfg.Predictor.BacktestExport.S3Path),
+			RoleArn: aws.String(cfg.Predictor.BacktestExport.RoleArn),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if cfg.Forecast.Predictor != cfg.Predictor.Name {
+		return fmt.Errorf("forecast %s references unknown predictor %s", cfg.Forecast.Name, cfg.Forecast.Predictor)
+	}
+
+	log.Println("create forecast")
+	forecastArn, err := f.CreateForecast(ctx, cfg.Forecast.Name, *predictorArn)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Export.Forecast != cfg.Forecast.Name {
+		return fmt.Errorf("export %s references unknown forecast %s", cfg.Export.Name, cfg.Export.Forecast)
+	}
+
+	log.Println("create forecastExportJob")
+	forecastExportJobArn, err := f.CreateForecastExportJob(ctx, cfg.Export.Name, cfg.Forecast.Name, *forecastArn, &types.S3Config{
+		Path:    aws.String(cfg.Export.S3Path),
+		RoleArn: aws.String(cfg.Export.RoleArn),
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Println("clean up")
+	if err := f.DeleteForecastExportJob(ctx, *forecastExportJobArn); err != nil {
+		return err
+	}
+	if err := f.DeleteForecast(ctx, *forecastArn); err != nil {
+		return err
+	}
+	if backtestExportJobArn != nil {
+		if err := f.DeletePredictorBacktestExportJob(ctx, *backtestExportJobArn); err != nil {
+			return err
+		}
+	}
+	if err := f.DeletePredictor(ctx, *predictorArn); err != nil {
+		return err
+	}
+	for _, arn := range datasetGroupArns {
+		if err := f.DeleteDatasetGroup(ctx, arn); err != nil {
+			return err
+		}
+	}
+	for _, arn := range importJobArns {
+		if err := f.DeleteDatasetImportJob(ctx, arn); err != nil {
+			return err
+		}
+	}
+	for _, arn := range datasetArns {
+		if err := f.DeleteDataset(ctx, arn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createDatasetImportJobs runs CreateDatasetImportJob for every j in jobs in
+// a worker pool bounded by f.MaxConcurrency, since import jobs can take an
+// hour each and are independent of one another.
+func (f Forecast) createDatasetImportJobs(ctx context.Context, jobs []ImportJobConfig, datasetArns map[string]string) ([]string, error) {
+	jobDatasetArns := make([]string, len(jobs))
+	for i, j := range jobs {
+		datasetArn, ok := datasetArns[j.DatasetName]
+		if !ok {
+			return nil, fmt.Errorf("import job %s references unknown dataset %s", j.Name, j.DatasetName)
+		}
+		jobDatasetArns[i] = datasetArn
+	}
+
+	arns := make([]string, len(jobs))
+	var mu sync.Mutex
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(f.MaxConcurrency)
+	for i, j := range jobs {
+		i, j := i, j
+		datasetArn := jobDatasetArns[i]
+		g.Go(func() error {
+			log.Printf("create importDatasetJob %s", j.Name)
+			arn, err := f.CreateDatasetImportJob(ctx, j.Name, j.DatasetName, datasetArn, j.TimeZone, &types.S3Config{
+				Path:    aws.String(j.S3Path),
+				RoleArn: aws.String(j.RoleArn),
+			})
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			arns[i] = *arn
+			mu.Unlock()
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return arns, nil
+}
+
+func (f Forecast) createDataset(ctx context.Context, d DatasetConfig) (*string, error) {
+	attrs := make([]types.SchemaAttribute, 0, len(d.Attributes))
+	for _, a := range d.Attributes {
+		attrs = append(attrs, types.SchemaAttribute{
+			AttributeName: aws.String(a.Name),
+			AttributeType: attributeType(a.Type),
+		})
+	}
+	return f.CreateDataset(ctx, d.Name, datasetType(d.Type), domain(d.Domain), d.Frequency, attrs)
+}
+
+// datasetType maps a config's "target" (default), "related" or
+// "item-metadata" to the DatasetType the CreateDataset API expects.
+func datasetType(s string) types.DatasetType {
+	switch strings.ToLower(s) {
+	case "related":
+		return types.DatasetTypeRelatedTimeSeries
+	case "item-metadata":
+		return types.DatasetTypeItemMetadata
+	default:
+		return types.DatasetTypeTargetTimeSeries
+	}
+}
+
+func (f Forecast) createPredictor(ctx context.Context, p PredictorConfig, datasetGroupArn string) (*string, error) {
+	if f.DryRun {
+		return f.logDryRun("predictor", p.Name,
+			fmt.Sprintf("DatasetGroupArn=%s, ForecastHorizon=%d, PerformAutoML=%v", datasetGroupArn, p.ForecastHorizon, p.PerformAutoML)), nil
+	}
+	arn, err := f.skipIfAlreadyExists("predictor", p.Name, func() (*string, error) {
+		predictor, err := f.svc.CreatePredictor(ctx, &forecast.CreatePredictorInput{
+			PredictorName:   aws.String(p.Name),
+			ForecastHorizon: aws.Int32(p.ForecastHorizon),
+			FeaturizationConfig: &types.FeaturizationConfig{
+				ForecastFrequency: aws.String(p.ForecastFrequency),
+			},
+			PerformAutoML: aws.Bool(p.PerformAutoML),
+			InputDataConfig: &types.InputDataConfig{
+				DatasetGroupArn:       aws.String(datasetGroupArn),
+				SupplementaryFeatures: supplementaryFeatures(p.SupplementaryFeatures),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return predictor.PredictorArn, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.waitForActive(ctx, "predictor", *arn, func() (*string, *int64, error) {
+		desc, err := f.svc.DescribePredictor(ctx, &forecast.DescribePredictorInput{
+			PredictorArn: arn,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		return desc.Status, desc.EstimatedTimeRemainingInMinutes, nil
+	}); err != nil {
+		return nil, err
+	}
+	return arn, nil
+}
+
+func attributeType(s string) types.AttributeType {
+	switch strings.ToLower(s) {
+	case "integer":
+		return types.AttributeTypeInteger
+	case "float":
+		return types.AttributeTypeFloat
+	case "timestamp":
+		return types.AttributeTypeTimestamp
+	case "geolocation":
+		return types.AttributeTypeGeolocation
+	default:
+		return types.AttributeTypeString
+	}
+}
+
+func domain(s string) types.Domain {
+	if s == "" {
+		return types.DomainCustom
+	}
+	return types.Domain(s)
+}
+
+func supplementaryFeatures(raw []string) []types.SupplementaryFeature {
+	features := make([]types.SupplementaryFeature, 0, len(raw))
+	for _, r := range raw {
+		parts := strings.SplitN(r, ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		features = append(features, types.SupplementaryFeature{
+			Name:  aws.String(parts[0]),
+			Value: aws.String(parts[1]),
+		})
+	}
+	return features
+}
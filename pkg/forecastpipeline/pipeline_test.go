@@ -0,0 +1,70 @@
+package forecastpipeline
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/forecast/types"
+)
+
+func TestDatasetType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want types.DatasetType
+	}{
+		{"related", types.DatasetTypeRelatedTimeSeries},
+		{"RELATED", types.DatasetTypeRelatedTimeSeries},
+		{"item-metadata", types.DatasetTypeItemMetadata},
+		{"", types.DatasetTypeTargetTimeSeries},
+		{"target", types.DatasetTypeTargetTimeSeries},
+	}
+	for _, tt := range tests {
+		if got := datasetType(tt.in); got != tt.want {
+			t.Errorf("datasetType(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAttributeType(t *testing.T) {
+	tests := []struct {
+		in   string
+		want types.AttributeType
+	}{
+		{"integer", types.AttributeTypeInteger},
+		{"FLOAT", types.AttributeTypeFloat},
+		{"timestamp", types.AttributeTypeTimestamp},
+		{"geolocation", types.AttributeTypeGeolocation},
+		{"", types.AttributeTypeString},
+		{"string", types.AttributeTypeString},
+	}
+	for _, tt := range tests {
+		if got := attributeType(tt.in); got != tt.want {
+			t.Errorf("attributeType(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDomain(t *testing.T) {
+	if got := domain(""); got != types.DomainCustom {
+		t.Errorf("domain(\"\") = %v, want %v", got, types.DomainCustom)
+	}
+	if got := domain("RETAIL"); got != types.Domain("RETAIL") {
+		t.Errorf("domain(\"RETAIL\") = %v, want RETAIL", got)
+	}
+}
+
+func TestSupplementaryFeatures(t *testing.T) {
+	got := supplementaryFeatures([]string{"holiday:US", "malformed", "weather:JP"})
+	want := []types.SupplementaryFeature{
+		{Name: aws.String("holiday"), Value: aws.String("US")},
+		{Name: aws.String("weather"), Value: aws.String("JP")},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("supplementaryFeatures() returned %d features, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if aws.ToString(got[i].Name) != aws.ToString(want[i].Name) || aws.ToString(got[i].Value) != aws.ToString(want[i].Value) {
+			t.Errorf("supplementaryFeatures()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package forecastpipeline
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/forecastquery"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// DataPoint is a single (timestamp, value) pair of a forecastquery
+// prediction, with Timestamp parsed into a time.Time instead of the raw
+// ISO-8601 string the API returns.
+type DataPoint struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+// QueryResult is the result of a single QueryForecast call, with each
+// quantile/mean series keyed by its name (e.g. "p10", "p50", "p90", "mean").
+type QueryResult struct {
+	ItemID      string
+	Predictions map[string][]DataPoint
+}
+
+// QuantileSeries pulls the commonly used p10/p50/p90/mean series out of a
+// QueryResult so callers don't have to juggle map[string][]DataPoint.
+type QuantileSeries struct {
+	P10  []DataPoint
+	P50  []DataPoint
+	P90  []DataPoint
+	Mean []DataPoint
+}
+
+func (r *QueryResult) QuantileSeries() QuantileSeries {
+	return QuantileSeries{
+		P10:  r.Predictions["p10"],
+		P50:  r.Predictions["p50"],
+		P90:  r.Predictions["p90"],
+		Mean: r.Predictions["mean"],
+	}
+}
+
+// QueryForecast wraps forecastquery.QueryForecast, converting the raw
+// per-quantile DataPoint lists into QueryResult.
+func (f Forecast) QueryForecast(ctx context.Context, forecastArn string, itemFilters map[string]string) (*QueryResult, error) {
+	if f.DryRun {
+		log.Printf("[dry-run] would query forecast %s with filters %v", forecastArn, itemFilters)
+		return &QueryResult{ItemID: itemFilters["item_id"], Predictions: map[string][]DataPoint{}}, nil
+	}
+
+	out, err := f.query.QueryForecast(ctx, &forecastquery.QueryForecastInput{
+		ForecastArn: &forecastArn,
+		Filters:     itemFilters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	predictions := make(map[string][]DataPoint, len(out.Forecast.Predictions))
+	for quantile, points := range out.Forecast.Predictions {
+		series := make([]DataPoint, 0, len(points))
+		for _, p := range points {
+			ts, err := time.Parse(time.RFC3339, *p.Timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("parsing timestamp %q: %w", *p.Timestamp, err)
+			}
+			series = append(series, DataPoint{Timestamp: ts, Value: *p.Value})
+		}
+		predictions[quantile] = series
+	}
+	return &QueryResult{ItemID: itemFilters["item_id"], Predictions: predictions}, nil
+}
+
+// OutputFormat selects how QueryForecastBatch renders results.
+type OutputFormat string
+
+const (
+	OutputFormatJSONLines OutputFormat = "jsonl"
+	OutputFormatCSV       OutputFormat = "csv"
+)
+
+// queryForecastBatchTPS is the default rate QueryForecastBatch calls
+// QueryForecast at, matching the forecastquery service's default TPS quota.
+const queryForecastBatchTPS = 10
+
+// QueryForecastBatch queries forecastArn for every item in itemIDs
+// concurrently, bounding in-flight requests to queryForecastBatchTPS (not
+// f.MaxConcurrency, which governs unrelated import-job parallelism) and
+// respecting the service's TPS with a token bucket, and writes the results
+// to w in either JSON Lines or CSV form.
+func (f Forecast) QueryForecastBatch(ctx context.Context, forecastArn string, itemIDs []string, w io.Writer, format OutputFormat) error {
+	limiter := rate.NewLimiter(rate.Limit(queryForecastBatchTPS), 1)
+
+	var (
+		mu        sync.Mutex
+		csvWriter *csv.Writer
+	)
+	if format == OutputFormatCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"item_id", "quantile", "timestamp", "value"}); err != nil {
+			return err
+		}
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(queryForecastBatchTPS)
+	for _, itemID := range itemIDs {
+		itemID := itemID
+		g.Go(func() error {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			result, err := f.QueryForecast(ctx, forecastArn, map[string]string{"item_id": itemID})
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch format {
+			case OutputFormatCSV:
+				return writeQueryResultCSV(csvWriter, result)
+			default:
+				return writeQueryResultJSONLines(w, result)
+			}
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	if csvWriter != nil {
+		csvWriter.Flush()
+		return csvWriter.Error()
+	}
+	return nil
+}
+
+type queryResultRecord struct {
+	ItemID    string    `json:"itemId"`
+	Quantile  string    `json:"quantile"`
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
+
+func writeQueryResultJSONLines(w io.Writer, result *QueryResult) error {
+	for quantile, points := range result.Predictions {
+		for _, p := range points {
+			b, err := json.Marshal(queryResultRecord{
+				ItemID:    result.ItemID,
+				Quantile:  quantile,
+				Timestamp: p.Timestamp,
+				Value:     p.Value,
+			})
+			if err != nil {
+				return err
+			}
+			if _, err := w.Write(append(b, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeQueryResultCSV(w *csv.Writer, result *QueryResult) error {
+	for quantile, points := range result.Predictions {
+		for _, p := range points {
+			if err := w.Write([]string{
+				result.ItemID,
+				quantile,
+				p.Timestamp.Format(time.RFC3339),
+				strconv.FormatFloat(p.Value, 'f', -1, 64),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package forecastpipeline
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQuantileSeries(t *testing.T) {
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &QueryResult{
+		ItemID: "item-1",
+		Predictions: map[string][]DataPoint{
+			"p10":  {{Timestamp: ts, Value: 1}},
+			"p50":  {{Timestamp: ts, Value: 2}},
+			"p90":  {{Timestamp: ts, Value: 3}},
+			"mean": {{Timestamp: ts, Value: 4}},
+		},
+	}
+	series := result.QuantileSeries()
+	if len(series.P10) != 1 || series.P10[0].Value != 1 {
+		t.Errorf("P10 = %+v, want one point with value 1", series.P10)
+	}
+	if len(series.Mean) != 1 || series.Mean[0].Value != 4 {
+		t.Errorf("Mean = %+v, want one point with value 4", series.Mean)
+	}
+}
+
+func TestWriteQueryResultJSONLines(t *testing.T) {
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &QueryResult{
+		ItemID:      "item-1",
+		Predictions: map[string][]DataPoint{"mean": {{Timestamp: ts, Value: 1.5}}},
+	}
+	var buf bytes.Buffer
+	if err := writeQueryResultJSONLines(&buf, result); err != nil {
+		t.Fatalf("writeQueryResultJSONLines() error = %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{`"itemId":"item-1"`, `"quantile":"mean"`, `"value":1.5`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("writeQueryResultJSONLines() output %q does not contain %q", got, want)
+		}
+	}
+	if !strings.HasSuffix(got, "\n") {
+		t.Errorf("writeQueryResultJSONLines() output %q does not end in a newline", got)
+	}
+}
+
+func TestWriteQueryResultCSV(t *testing.T) {
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &QueryResult{
+		ItemID:      "item-1",
+		Predictions: map[string][]DataPoint{"mean": {{Timestamp: ts, Value: 1.5}}},
+	}
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := writeQueryResultCSV(w, result); err != nil {
+		t.Fatalf("writeQueryResultCSV() error = %v", err)
+	}
+	w.Flush()
+	want := "item-1,mean,2021-01-01T00:00:00Z,1.5\n"
+	if buf.String() != want {
+		t.Errorf("writeQueryResultCSV() output = %q, want %q", buf.String(), want)
+	}
+}